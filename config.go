@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Update policies a submodule entry in the config file can declare.
+const (
+	policyLatest      = "latest"       // track the tip of the submodule's remote branch
+	policyPinned      = "pinned"       // stay on the exact commit/tag/branch given in Ref
+	policyTagGlob     = "tag-glob"     // resolve Ref as a glob over tag names, e.g. "release-*"
+	policySemverRange = "semver-range" // resolve Ref as a semver range/tilde, e.g. "^v1.2.0"
+)
+
+// configFileNames are the supported config file names, checked in this order
+// relative to the git root. The first one found wins.
+var configFileNames = []string{".submoduler.yaml", ".submoduler.yml", ".submoduler.toml"}
+
+// SubmoduleConfig describes how a single submodule should be resolved when no
+// matching CLI override is given.
+type SubmoduleConfig struct {
+	Ref    string `yaml:"ref,omitempty" toml:"ref,omitempty"`
+	Policy string `yaml:"policy,omitempty" toml:"policy,omitempty"`
+	Branch string `yaml:"branch,omitempty" toml:"branch,omitempty"`
+}
+
+// Config is the parsed representation of a .submoduler.yaml/.submoduler.toml
+// file: a map of submodule path to how it should be kept up to date.
+type Config struct {
+	Submodules map[string]SubmoduleConfig `yaml:"submodules" toml:"submodules"`
+}
+
+// loadConfig looks for a submoduler config file at the git root and parses
+// it. If no config file is present, it returns an empty, non-nil Config so
+// callers never need to nil-check it.
+func loadConfig(rootDir string) (*Config, error) {
+	for _, name := range configFileNames {
+		path := filepath.Join(rootDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("reading config %s: %w", path, err)
+		}
+
+		cfg := &Config{}
+		if filepath.Ext(name) == ".toml" {
+			if err := toml.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("parsing config %s: %w", path, err)
+			}
+		} else {
+			if err := yaml.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("parsing config %s: %w", path, err)
+			}
+		}
+		if cfg.Submodules == nil {
+			cfg.Submodules = make(map[string]SubmoduleConfig)
+		}
+		return cfg, nil
+	}
+
+	return &Config{Submodules: make(map[string]SubmoduleConfig)}, nil
+}
+
+// resolveSpecificRefs computes the final path->ref overrides to apply this
+// run. It starts from the pinned refs and branch overrides declared in the
+// config file, then merges in the CLI-supplied "path=ref" arguments, which
+// always take precedence over the config.
+func resolveSpecificRefs(cfg *Config, args []string) map[string]string {
+	refs := make(map[string]string)
+
+	for path, sub := range cfg.Submodules {
+		switch sub.Policy {
+		case policyPinned:
+			if sub.Ref != "" {
+				refs[path] = sub.Ref
+			}
+		case policyTagGlob:
+			if sub.Ref != "" {
+				refs[path] = "tag:" + sub.Ref
+			}
+		case policySemverRange:
+			if sub.Ref != "" {
+				if strings.HasPrefix(sub.Ref, "^") || strings.HasPrefix(sub.Ref, "~") {
+					refs[path] = sub.Ref
+				} else {
+					refs[path] = "^" + sub.Ref // default to caret when the config omits the range kind
+				}
+			}
+		case policyLatest, "":
+			if sub.Branch != "" {
+				refs[path] = "origin/" + sub.Branch
+			}
+		}
+	}
+
+	for path, ref := range parseArgs(args) {
+		refs[path] = ref
+	}
+
+	return refs
+}
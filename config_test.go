@@ -0,0 +1,115 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveSpecificRefs(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *Config
+		args     []string
+		expected map[string]string
+	}{
+		{
+			name:     "empty config and args",
+			cfg:      &Config{Submodules: map[string]SubmoduleConfig{}},
+			args:     []string{},
+			expected: map[string]string{},
+		},
+		{
+			name: "pinned config entry",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/thanos": {Ref: "v1.2.3", Policy: policyPinned},
+			}},
+			args:     []string{},
+			expected: map[string]string{"vendor/thanos": "v1.2.3"},
+		},
+		{
+			name: "latest policy with branch override",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/prometheus": {Policy: policyLatest, Branch: "release-2.50"},
+			}},
+			args:     []string{},
+			expected: map[string]string{"vendor/prometheus": "origin/release-2.50"},
+		},
+		{
+			name: "latest policy without branch override is left for remote update",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/loki": {Policy: policyLatest},
+			}},
+			args:     []string{},
+			expected: map[string]string{},
+		},
+		{
+			name: "CLI arg overrides config pin",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/thanos": {Ref: "v1.2.3", Policy: policyPinned},
+			}},
+			args:     []string{"vendor/thanos=v1.3.0"},
+			expected: map[string]string{"vendor/thanos": "v1.3.0"},
+		},
+		{
+			name: "CLI arg adds to config entries",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/thanos": {Ref: "v1.2.3", Policy: policyPinned},
+			}},
+			args: []string{"vendor/loki=main"},
+			expected: map[string]string{
+				"vendor/thanos": "v1.2.3",
+				"vendor/loki":   "main",
+			},
+		},
+		{
+			name: "tag-glob policy is prefixed for resolution",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/loki": {Ref: "release-*", Policy: policyTagGlob},
+			}},
+			args:     []string{},
+			expected: map[string]string{"vendor/loki": "tag:release-*"},
+		},
+		{
+			name: "semver-range policy keeps an explicit caret or tilde",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/a": {Ref: "^v1.2.0", Policy: policySemverRange},
+				"vendor/b": {Ref: "~v1.2", Policy: policySemverRange},
+			}},
+			args: []string{},
+			expected: map[string]string{
+				"vendor/a": "^v1.2.0",
+				"vendor/b": "~v1.2",
+			},
+		},
+		{
+			name: "semver-range policy defaults to caret",
+			cfg: &Config{Submodules: map[string]SubmoduleConfig{
+				"vendor/thanos": {Ref: "v1.2.0", Policy: policySemverRange},
+			}},
+			args:     []string{},
+			expected: map[string]string{"vendor/thanos": "^v1.2.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := resolveSpecificRefs(tt.cfg, tt.args)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("resolveSpecificRefs(%v, %v) = %v, want %v", tt.cfg, tt.args, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	cfg, err := loadConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("loadConfig() returned error for missing config: %v", err)
+	}
+	if cfg == nil || cfg.Submodules == nil {
+		t.Fatalf("loadConfig() = %v, want non-nil Config with initialized Submodules map", cfg)
+	}
+	if len(cfg.Submodules) != 0 {
+		t.Errorf("loadConfig() with no file present = %v, want empty map", cfg.Submodules)
+	}
+}
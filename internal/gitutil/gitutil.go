@@ -0,0 +1,245 @@
+// Package gitutil wraps the git CLI invocations submoduler needs behind a
+// small, testable API, instead of scattering ad-hoc exec.Command calls
+// across the CLI. It follows the shape jiri's Git type uses: a struct
+// carrying the working directory and options, with methods for the specific
+// operations callers need.
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitError wraps a failed git invocation with the context needed to diagnose
+// it, instead of losing everything but a bare exit code.
+type GitError struct {
+	Root   string
+	Args   []string
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("git %s (in %s): %v\n%s", strings.Join(e.Args, " "), e.Root, e.Err, strings.TrimSpace(e.Stderr))
+}
+
+func (e *GitError) Unwrap() error { return e.Err }
+
+// Git runs git commands against a fixed working directory.
+type Git struct {
+	// Dir is the directory git commands are run in.
+	Dir string
+	// Verbose streams command output to os.Stdout/os.Stderr as it runs, in
+	// addition to capturing it.
+	Verbose bool
+}
+
+// New returns a Git that runs commands in dir.
+func New(dir string, verbose bool) *Git {
+	return &Git{Dir: dir, Verbose: verbose}
+}
+
+// run executes `git args...` in g.Dir and returns its trimmed stdout. On
+// failure it returns a *GitError carrying the captured output.
+func (g *Git) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
+
+	var stdout, stderr bytes.Buffer
+	if g.Verbose {
+		fmt.Printf("-> Running in %s: git %s\n", g.Dir, strings.Join(args, " "))
+		cmd.Stdout = io.MultiWriter(&stdout, os.Stdout)
+		cmd.Stderr = io.MultiWriter(&stderr, os.Stderr)
+	} else {
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), &GitError{Root: g.Dir, Args: args, Stdout: stdout.String(), Stderr: stderr.String(), Err: err}
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// RootDir returns the top-level directory of the current Git repository.
+func RootDir(ctx context.Context) (string, error) {
+	return (&Git{}).run(ctx, "rev-parse", "--show-toplevel")
+}
+
+// InitSubmodules initializes and clones any missing submodules, recursively.
+// This handles cases where a user has cloned the repo but not run
+// `git submodule update --init`.
+func (g *Git) InitSubmodules(ctx context.Context) error {
+	_, err := g.run(ctx, "submodule", "update", "--init", "--recursive", "--progress")
+	return err
+}
+
+// SubmoduleStatus returns the path of every submodule registered in the repo.
+func (g *Git) SubmoduleStatus(ctx context.Context) ([]string, error) {
+	out, err := g.run(ctx, "submodule", "status")
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// The output format is ` [commit] [path] ([branch])`. We just need the path.
+		// The leading space is present on modified/uninitialized submodules.
+		fields := strings.Fields(line)
+		if len(fields) >= 2 {
+			paths = append(paths, fields[1])
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// FetchOpts controls which refs Fetch retrieves from the remote.
+type FetchOpts struct {
+	All  bool // fetch from all remotes
+	Tags bool // also fetch tags
+}
+
+// Fetch retrieves refs from the remote according to opts.
+func (g *Git) Fetch(ctx context.Context, opts FetchOpts) error {
+	args := []string{"fetch"}
+	if opts.All {
+		args = append(args, "--all")
+	}
+	if opts.Tags {
+		args = append(args, "--tags")
+	}
+	_, err := g.run(ctx, args...)
+	return err
+}
+
+// Checkout checks out the given commit, tag, or branch.
+func (g *Git) Checkout(ctx context.Context, ref string) error {
+	_, err := g.run(ctx, "checkout", ref)
+	return err
+}
+
+// RevParse resolves ref to a commit SHA.
+func (g *Git) RevParse(ctx context.Context, ref string) (string, error) {
+	return g.run(ctx, "rev-parse", ref)
+}
+
+// RemoteDefaultBranch returns the name of the branch "origin"'s HEAD points
+// at (e.g. "main"), without requiring a prior clone or fetch.
+func (g *Git) RemoteDefaultBranch(ctx context.Context) (string, error) {
+	out, err := g.run(ctx, "ls-remote", "--symref", "origin", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	// The line we want looks like "ref: refs/heads/main\tHEAD".
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD" {
+			return strings.TrimPrefix(fields[1], "refs/heads/"), nil
+		}
+	}
+	return "", fmt.Errorf("could not determine origin's default branch")
+}
+
+// Add stages the given paths.
+func (g *Git) Add(ctx context.Context, paths ...string) error {
+	args := append([]string{"add"}, paths...)
+	_, err := g.run(ctx, args...)
+	return err
+}
+
+// Commit creates a commit from the currently staged changes with the given
+// message.
+func (g *Git) Commit(ctx context.Context, message string) error {
+	_, err := g.run(ctx, "commit", "-m", message)
+	return err
+}
+
+// LsRemoteRef resolves ref (a branch name, or "" for the remote's default
+// branch) to its current commit SHA on the remote, without requiring a
+// prior fetch.
+func (g *Git) LsRemoteRef(ctx context.Context, ref string) (string, error) {
+	target := ref
+	if target == "" {
+		target = "HEAD"
+	}
+	out, err := g.run(ctx, "ls-remote", "origin", target)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no such ref %q on remote", ref)
+	}
+	return fields[0], nil
+}
+
+// LogOneline returns the one-line summary of each commit reachable from
+// newSHA but not oldSHA, most recent first.
+func (g *Git) LogOneline(ctx context.Context, oldSHA, newSHA string) ([]string, error) {
+	out, err := g.run(ctx, "log", "--oneline", fmt.Sprintf("%s..%s", oldSHA, newSHA))
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// SubmoduleBranch returns the branch configured for path in the .gitmodules
+// file at rootDir (submodule.<path>.branch), or "" if none is set, meaning
+// the remote's default branch should be tracked.
+func SubmoduleBranch(ctx context.Context, rootDir, path string) (string, error) {
+	g := &Git{Dir: rootDir}
+	out, err := g.run(ctx, "config", "-f", ".gitmodules", fmt.Sprintf("submodule.%s.branch", path))
+	if err != nil {
+		var gitErr *GitError
+		if errors.As(err, &gitErr) {
+			return "", nil // not set: git config exits non-zero when the key is absent
+		}
+		return "", err
+	}
+	return out, nil
+}
+
+// ListRemoteTags lists the tag names advertised by the remote, without
+// requiring a prior fetch.
+func (g *Git) ListRemoteTags(ctx context.Context) ([]string, error) {
+	out, err := g.run(ctx, "ls-remote", "--tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		// Each line is "<sha>\trefs/tags/<name>", with annotated tags also
+		// producing a "<name>^{}" line pointing at the dereferenced commit.
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name := strings.TrimPrefix(fields[1], "refs/tags/")
+		name = strings.TrimSuffix(name, "^{}")
+		tags = append(tags, name)
+	}
+	return tags, scanner.Err()
+}
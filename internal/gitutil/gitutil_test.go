@@ -0,0 +1,236 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// initTestRepo creates a small git repository with two commits on main and
+// returns its directory along with the SHA of each commit.
+func initTestRepo(t *testing.T) (dir string, firstSHA string, secondSHA string) {
+	t.Helper()
+	dir = t.TempDir()
+	ctx := context.Background()
+	g := New(dir, false)
+
+	mustRun := func(args ...string) {
+		t.Helper()
+		if _, err := g.run(ctx, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+
+	mustRun("init", "-q")
+	mustRun("config", "user.email", "test@example.com")
+	mustRun("config", "user.name", "Test")
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one"), 0o644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	mustRun("add", ".")
+	mustRun("commit", "-q", "-m", "first")
+	firstSHA, err := g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("two"), 0o644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	mustRun("commit", "-q", "-am", "second")
+	secondSHA, err = g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+
+	return dir, firstSHA, secondSHA
+}
+
+func TestCheckoutAndRevParse(t *testing.T) {
+	dir, firstSHA, secondSHA := initTestRepo(t)
+	ctx := context.Background()
+	g := New(dir, false)
+
+	head, err := g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+	if head != secondSHA {
+		t.Fatalf("RevParse(HEAD) = %s, want %s", head, secondSHA)
+	}
+
+	if err := g.Checkout(ctx, firstSHA); err != nil {
+		t.Fatalf("Checkout(%s): %v", firstSHA, err)
+	}
+	head, err = g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+	if head != firstSHA {
+		t.Fatalf("after Checkout, RevParse(HEAD) = %s, want %s", head, firstSHA)
+	}
+}
+
+func TestCheckoutInvalidRefReturnsGitError(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx := context.Background()
+	g := New(dir, false)
+
+	err := g.Checkout(ctx, "does-not-exist")
+	if err == nil {
+		t.Fatal("Checkout of a nonexistent ref should fail")
+	}
+
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("error = %v (%T), want a *GitError", err, err)
+	}
+	if gitErr.Root != dir {
+		t.Errorf("GitError.Root = %s, want %s", gitErr.Root, dir)
+	}
+	if len(gitErr.Args) == 0 || gitErr.Args[0] != "checkout" {
+		t.Errorf("GitError.Args = %v, want to start with checkout", gitErr.Args)
+	}
+}
+
+func TestSubmoduleStatusNoSubmodules(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	g := New(dir, false)
+
+	paths, err := g.SubmoduleStatus(context.Background())
+	if err != nil {
+		t.Fatalf("SubmoduleStatus: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("SubmoduleStatus() = %v, want empty", paths)
+	}
+}
+
+func TestVerboseStreamsToStdout(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx := context.Background()
+	g := New(dir, true)
+
+	if _, err := g.RevParse(ctx, "HEAD"); err != nil {
+		t.Fatalf("RevParse in verbose mode failed: %v", err)
+	}
+}
+
+func TestLogOneline(t *testing.T) {
+	dir, firstSHA, secondSHA := initTestRepo(t)
+	ctx := context.Background()
+	g := New(dir, false)
+
+	commits, err := g.LogOneline(ctx, firstSHA, secondSHA)
+	if err != nil {
+		t.Fatalf("LogOneline: %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("LogOneline() = %v, want 1 commit", commits)
+	}
+
+	commits, err = g.LogOneline(ctx, secondSHA, secondSHA)
+	if err != nil {
+		t.Fatalf("LogOneline: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("LogOneline(x, x) = %v, want none", commits)
+	}
+}
+
+func TestLsRemoteRef(t *testing.T) {
+	dir, _, secondSHA := initTestRepo(t)
+	ctx := context.Background()
+	g := New(dir, false)
+
+	if _, err := g.run(ctx, "remote", "add", "origin", dir); err != nil {
+		t.Fatalf("adding origin: %v", err)
+	}
+
+	sha, err := g.LsRemoteRef(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("LsRemoteRef: %v", err)
+	}
+	if sha != secondSHA {
+		t.Errorf("LsRemoteRef(HEAD) = %s, want %s", sha, secondSHA)
+	}
+}
+
+func TestLsRemoteRefNoSuchRef(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx := context.Background()
+	g := New(dir, false)
+
+	if _, err := g.run(ctx, "remote", "add", "origin", dir); err != nil {
+		t.Fatalf("adding origin: %v", err)
+	}
+
+	if _, err := g.LsRemoteRef(ctx, "does-not-exist"); err == nil {
+		t.Error("LsRemoteRef of a nonexistent ref should fail")
+	}
+}
+
+func TestSubmoduleBranch(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx := context.Background()
+
+	if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte("[submodule \"vendor/thanos\"]\n\tpath = vendor/thanos\n\turl = https://example.com/thanos.git\n\tbranch = release-2.50\n"), 0o644); err != nil {
+		t.Fatalf("writing .gitmodules: %v", err)
+	}
+
+	branch, err := SubmoduleBranch(ctx, dir, "vendor/thanos")
+	if err != nil {
+		t.Fatalf("SubmoduleBranch: %v", err)
+	}
+	if branch != "release-2.50" {
+		t.Errorf("SubmoduleBranch() = %q, want %q", branch, "release-2.50")
+	}
+
+	branch, err = SubmoduleBranch(ctx, dir, "vendor/unset")
+	if err != nil {
+		t.Fatalf("SubmoduleBranch: %v", err)
+	}
+	if branch != "" {
+		t.Errorf("SubmoduleBranch() for unset branch = %q, want empty", branch)
+	}
+}
+
+func TestRootDir(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx := context.Background()
+
+	// RootDir uses the process's current working directory, so point the
+	// test process there for the duration of the assertion.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatalf("restoring cwd: %v", err)
+		}
+	}()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+
+	root, err := RootDir(ctx)
+	if err != nil {
+		t.Fatalf("RootDir: %v", err)
+	}
+
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	if resolvedRoot != resolvedDir {
+		t.Errorf("RootDir() = %s, want %s", resolvedRoot, resolvedDir)
+	}
+}
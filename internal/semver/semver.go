@@ -0,0 +1,167 @@
+// Package semver implements just enough of the semver spec to sort and
+// match submodule tags: parsing "vMAJOR.MINOR.PATCH[-pre][+build]" strings
+// and testing them against caret ("^v1.2.0") and tilde ("~v1.2") ranges.
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is not retained,
+// since it has no bearing on precedence.
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string
+}
+
+// Parse parses a full "vMAJOR.MINOR.PATCH[-pre][+build]" string. The leading
+// "v" is optional. ok is false if s isn't a well-formed, fully-specified
+// version.
+func Parse(s string) (v Version, ok bool) {
+	base, pre := splitPreRelease(s)
+	parts := strings.Split(base, ".")
+	if len(parts) != 3 {
+		return Version{}, false
+	}
+
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	patch, err3 := strconv.Atoi(parts[2])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return Version{}, false
+	}
+
+	return Version{Major: major, Minor: minor, Patch: patch, Pre: pre}, true
+}
+
+// ParseConstraint parses a version that may omit its patch component, as
+// tilde ranges do ("~v1.2"). hasPatch reports whether a patch number was
+// given.
+func ParseConstraint(s string) (v Version, hasPatch bool, ok bool) {
+	base, pre := splitPreRelease(s)
+	parts := strings.SplitN(base, ".", 3)
+	if len(parts) < 2 {
+		return Version{}, false, false
+	}
+
+	major, err1 := strconv.Atoi(parts[0])
+	minor, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return Version{}, false, false
+	}
+	v = Version{Major: major, Minor: minor, Pre: pre}
+
+	if len(parts) == 3 {
+		patch, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return Version{}, false, false
+		}
+		v.Patch = patch
+		hasPatch = true
+	}
+	return v, hasPatch, true
+}
+
+// splitPreRelease strips an optional "v" prefix and "+build" suffix from s
+// and separates out a "-pre" suffix if present.
+func splitPreRelease(s string) (base, pre string) {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
+// Compare returns -1, 0, or 1 as a is less than, equal to, or greater than b,
+// following semver precedence (a version with a pre-release is lower than
+// the same version without one).
+func Compare(a, b Version) int {
+	if a.Major != b.Major {
+		return cmpInt(a.Major, b.Major)
+	}
+	if a.Minor != b.Minor {
+		return cmpInt(a.Minor, b.Minor)
+	}
+	if a.Patch != b.Patch {
+		return cmpInt(a.Patch, b.Patch)
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// comparePre compares pre-release strings dot-segment by dot-segment,
+// numerically where both segments are numbers and lexically otherwise. No
+// pre-release outranks any pre-release.
+func comparePre(a, b string) int {
+	if a == "" && b == "" {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+		if aerr == nil && berr == nil {
+			return cmpInt(an, bn)
+		}
+		return strings.Compare(as[i], bs[i])
+	}
+	return cmpInt(len(as), len(bs))
+}
+
+// MatchCaret reports whether candidate satisfies a caret constraint, given
+// as the base version without its leading "^" (e.g. "v1.2.0" for the range
+// "^v1.2.0"): same major version, and at least the given version.
+func MatchCaret(constraint string, candidate Version) (bool, error) {
+	base, ok := Parse(constraint)
+	if !ok {
+		return false, fmt.Errorf("invalid caret constraint %q: want vMAJOR.MINOR.PATCH", constraint)
+	}
+	if candidate.Major != base.Major {
+		return false, nil
+	}
+	return Compare(candidate, base) >= 0, nil
+}
+
+// MatchTilde reports whether candidate satisfies a tilde constraint, given
+// as the base version without its leading "~" (e.g. "v1.2" or "v1.2.3" for
+// the ranges "~v1.2"/"~v1.2.3"): same major and minor version, and at least
+// the given version if a patch was specified.
+func MatchTilde(constraint string, candidate Version) (bool, error) {
+	base, hasPatch, ok := ParseConstraint(constraint)
+	if !ok {
+		return false, fmt.Errorf("invalid tilde constraint %q: want vMAJOR.MINOR[.PATCH]", constraint)
+	}
+	if candidate.Major != base.Major || candidate.Minor != base.Minor {
+		return false, nil
+	}
+	if hasPatch && Compare(candidate, base) < 0 {
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,117 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   Version
+		wantOK bool
+	}{
+		{"v1.2.3", Version{1, 2, 3, ""}, true},
+		{"1.2.3", Version{1, 2, 3, ""}, true},
+		{"v1.2.3-rc.1", Version{1, 2, 3, "rc.1"}, true},
+		{"v1.2.3+build5", Version{1, 2, 3, ""}, true},
+		{"v1.2.3-rc.1+build5", Version{1, 2, 3, "rc.1"}, true},
+		{"v1.2", Version{}, false},
+		{"not-a-version", Version{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if ok != tt.wantOK {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.in, ok, tt.wantOK)
+			continue
+		}
+		if ok && got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.2.3", "v1.2.4", -1},
+		{"v1.3.0", "v1.2.9", 1},
+		{"v2.0.0", "v1.9.9", 1},
+		{"v1.0.0-rc.1", "v1.0.0", -1},
+		{"v1.0.0-rc.2", "v1.0.0-rc.1", 1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+	}
+
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if got := Compare(a, b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchCaret(t *testing.T) {
+	tests := []struct {
+		constraint string
+		candidate  string
+		want       bool
+	}{
+		{"v1.2.0", "v1.2.0", true},
+		{"v1.2.0", "v1.5.9", true},
+		{"v1.2.0", "v1.1.9", false},
+		{"v1.2.0", "v2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		candidate, ok := Parse(tt.candidate)
+		if !ok {
+			t.Fatalf("test candidate %q should parse", tt.candidate)
+		}
+		got, err := MatchCaret(tt.constraint, candidate)
+		if err != nil {
+			t.Errorf("MatchCaret(%q, %q) unexpected error: %v", tt.constraint, tt.candidate, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MatchCaret(%q, %q) = %v, want %v", tt.constraint, tt.candidate, got, tt.want)
+		}
+	}
+}
+
+func TestMatchCaretInvalidConstraint(t *testing.T) {
+	if _, err := MatchCaret("not-a-version", Version{Major: 1}); err == nil {
+		t.Error("MatchCaret with an invalid constraint should return an error")
+	}
+}
+
+func TestMatchTilde(t *testing.T) {
+	tests := []struct {
+		constraint string
+		candidate  string
+		want       bool
+	}{
+		{"v1.2", "v1.2.0", true},
+		{"v1.2", "v1.2.9", true},
+		{"v1.2", "v1.3.0", false},
+		{"v1.2.3", "v1.2.2", false},
+		{"v1.2.3", "v1.2.3", true},
+		{"v1.2.3", "v1.2.9", true},
+	}
+
+	for _, tt := range tests {
+		candidate, ok := Parse(tt.candidate)
+		if !ok {
+			t.Fatalf("test candidate %q should parse", tt.candidate)
+		}
+		got, err := MatchTilde(tt.constraint, candidate)
+		if err != nil {
+			t.Errorf("MatchTilde(%q, %q) unexpected error: %v", tt.constraint, tt.candidate, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MatchTilde(%q, %q) = %v, want %v", tt.constraint, tt.candidate, got, tt.want)
+		}
+	}
+}
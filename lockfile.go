@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lockFileName is the name of the lockfile written after a successful run,
+// relative to the git root.
+const lockFileName = ".submoduler.lock.yaml"
+
+// Lockfile records the exact commit resolved for each submodule during a run,
+// so a later `submoduler --frozen` run can reproduce the same tree without
+// re-resolving any refs.
+type Lockfile struct {
+	Submodules map[string]string `yaml:"submodules"`
+}
+
+// loadLockfile reads the lockfile from the git root. It returns an error if
+// the lockfile does not exist, since callers that need it (--frozen) cannot
+// proceed without it.
+func loadLockfile(rootDir string) (*Lockfile, error) {
+	path := filepath.Join(rootDir, lockFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no lockfile found at %s", path)
+		}
+		return nil, fmt.Errorf("reading lockfile %s: %w", path, err)
+	}
+
+	lock := &Lockfile{}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("parsing lockfile %s: %w", path, err)
+	}
+	if lock.Submodules == nil {
+		lock.Submodules = make(map[string]string)
+	}
+	return lock, nil
+}
+
+// writeLockfile writes the lockfile to the git root, overwriting any
+// existing one.
+func writeLockfile(rootDir string, lock *Lockfile) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("marshaling lockfile: %w", err)
+	}
+
+	path := filepath.Join(rootDir, lockFileName)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing lockfile %s: %w", path, err)
+	}
+	return nil
+}
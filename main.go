@@ -1,52 +1,84 @@
 package main
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
+	"os/signal"
+	"runtime"
 	"strings"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
 )
 
 // Global flag for verbose output.
 var verbose bool
 
 // main is the entry point of the script.
-// It parses command-line arguments for specific submodule commits or tags
-// and then updates all submodules accordingly.
+// It parses command-line arguments for specific submodule commits or tags,
+// merges them with any `.submoduler.yaml`/`.submoduler.toml` config found at
+// the git root, and then updates all submodules accordingly.
 //
 // Usage:
-// go run main.go [-v] [path/to/submodule1=commit_hash] [path/to/submodule2=v1.2.3]
+// go run main.go [-v] [-frozen] [-j N] [-n] [path/to/submodule1=commit_hash] [path/to/submodule2=v1.2.3]
 func main() {
 	// Define command-line flags.
+	var frozen bool
+	var workers int
+	var dryRun bool
+	var commit bool
+	var prBodyPath string
 	flag.BoolVar(&verbose, "v", false, "Enable verbose output to see the commands being run.")
+	flag.BoolVar(&frozen, "frozen", false, "Reproduce the exact tree recorded in .submoduler.lock.yaml instead of resolving refs.")
+	flag.IntVar(&workers, "j", runtime.NumCPU(), "Number of submodules to process concurrently.")
+	flag.BoolVar(&dryRun, "n", false, "Show what would change without updating any submodule.")
+	flag.BoolVar(&dryRun, "dry-run", false, "Show what would change without updating any submodule.")
+	flag.BoolVar(&commit, "commit", false, "After updating, stage .gitmodules and the updated submodules and commit the bump in the parent repo.")
+	flag.StringVar(&prBodyPath, "pr-body", "", "Write a Markdown summary of the bump to this file, suitable for a pull request description.")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "%s [-v] [submodule1=ref] [submodule2=ref] ...\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "%s [-v] [-frozen] [-j N] [-n] [-commit] [-pr-body file] [submodule1=ref] [submodule2=ref] ...\n\n", os.Args[0])
 		fmt.Fprintln(os.Stderr, "This script updates git submodules.")
 		fmt.Fprintln(os.Stderr, "By default, it pulls the latest commit for each submodule's tracked branch.")
 		fmt.Fprintln(os.Stderr, "You can specify a commit, tag, or branch for a submodule using the 'path=ref' format (e.g., my_sub=v1.2.3).")
+		fmt.Fprintln(os.Stderr, "ref can also be a semver range ('^v1.2.0', '~v1.2') or a tag glob ('tag:release-*'), resolved against the submodule's remote tags.")
+		fmt.Fprintln(os.Stderr, "Pins and update policies can also be declared in a .submoduler.yaml or .submoduler.toml file at the git root; CLI args override the config.")
+		fmt.Fprintln(os.Stderr, "Submodules are processed concurrently by a pool of -j workers; Ctrl-C aborts any in-flight work.")
+		fmt.Fprintln(os.Stderr, "Pass -n/--dry-run to print the plan (old..new commit range and pulled-in commits) without changing anything.")
+		fmt.Fprintln(os.Stderr, "Pass -commit to create a commit in the parent repo summarizing the bump, and -pr-body to also write it to a Markdown file.")
 		fmt.Fprintln(os.Stderr, "Options:")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
 
-	// A map to hold submodule paths and the specific ref (commit/tag/branch) to check out.
-	// e.g., {"path/to/submodule": "a1b2c3d"} or {"path/to/submodule": "v1.2.3"}
-	specificRefs := parseArgs(flag.Args())
+	// Cancelling this context (e.g. via Ctrl-C) aborts in-flight workers cleanly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
 	// Get the root directory of the Git repository.
-	rootDir, err := getGitRootDir()
+	rootDir, err := gitutil.RootDir(ctx)
 	if err != nil {
 		fmt.Printf("Error: Not a git repository or git command not found. %v\n", err)
 		os.Exit(1)
 	}
+	root := gitutil.New(rootDir, verbose)
+
+	// Load the config file, if any, and merge in the CLI overrides.
+	cfg, err := loadConfig(rootDir)
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// A map to hold submodule paths and the specific ref (commit/tag/branch) to check out.
+	// e.g., {"path/to/submodule": "a1b2c3d"} or {"path/to/submodule": "v1.2.3"}
+	specificRefs := resolveSpecificRefs(cfg, flag.Args())
 
 	// First, ensure all submodules are initialized and cloned.
 	// This handles cases where a user has cloned the repo but not run `git submodule update --init`.
 	fmt.Println("Initializing and cloning any missing submodules...")
-	if err := runCommand(rootDir, "git", "submodule", "update", "--init", "--recursive", "--progress"); err != nil {
+	if err := root.InitSubmodules(ctx); err != nil {
 		fmt.Printf("Error initializing submodules: %v\n", err)
 		os.Exit(1)
 	}
@@ -54,7 +86,7 @@ func main() {
 	fmt.Println("---------------------------------")
 
 	// Get a list of all submodule paths.
-	submodules, err := getSubmodules(rootDir)
+	submodules, err := root.SubmoduleStatus(ctx)
 	if err != nil {
 		fmt.Printf("Error getting submodules: %v\n", err)
 		os.Exit(1)
@@ -65,51 +97,80 @@ func main() {
 		return
 	}
 
-	fmt.Printf("Found %d submodules. Starting update...\n\n", len(submodules))
-
-	// Separate submodules into two groups: those with specific refs and those to be updated to latest.
-	var submodulesToUpdateRemote []string
-	submodulesWithSpecificRefs := make(map[string]string)
+	fmt.Printf("Found %d submodules. Starting update with %d worker(s)...\n\n", len(submodules), workers)
+
+	// In --frozen mode, ignore the config/CLI-resolved refs entirely and pin
+	// every submodule to the commit recorded in the lockfile. Submodules the
+	// lockfile doesn't know about are skipped rather than falling back to
+	// "latest", since that would defeat the point of a reproducible run.
+	if frozen {
+		lock, err := loadLockfile(rootDir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		specificRefs = make(map[string]string)
+		for _, path := range submodules {
+			sha, ok := lock.Submodules[path]
+			if !ok {
+				fmt.Printf("Warning: no locked commit for submodule %s; skipping\n", path)
+				continue
+			}
+			specificRefs[path] = sha
+		}
+	}
 
+	// Build one job per submodule: a specific ref to check out, or an empty
+	// ref meaning "update to the latest commit on the tracked branch". In
+	// --frozen mode, submodules without a locked commit are dropped entirely.
+	var jobs []submoduleJob
 	for _, path := range submodules {
 		if ref, ok := specificRefs[path]; ok {
-			submodulesWithSpecificRefs[path] = ref
-		} else {
-			submodulesToUpdateRemote = append(submodulesToUpdateRemote, path)
+			jobs = append(jobs, submoduleJob{path: path, ref: ref})
+		} else if !frozen {
+			jobs = append(jobs, submoduleJob{path: path})
 		}
 	}
 
-	// Process submodules with specific refs first by cd-ing into them and checking out the ref.
-	for path, ref := range submodulesWithSpecificRefs {
-		fmt.Printf("--- Processing submodule: %s -> %s ---\n", path, ref)
-		submoduleDir := rootDir + "/" + path
+	results := runSubmoduleJobs(ctx, rootDir, jobs, workers, dryRun)
+	if dryRun {
+		printPlan(results)
+		fmt.Println("Dry run complete; nothing was changed.")
+		return
+	}
+	printSummary(results)
 
-		// Fetch all changes, including tags, from the remote.
-		if err := runCommand(submoduleDir, "git", "fetch", "--all", "--tags"); err != nil {
-			fmt.Printf("Error fetching in %s: %v\n", path, err)
-			continue // Move to the next submodule on error.
+	if prBodyPath != "" {
+		if err := writePRBody(prBodyPath, results); err != nil {
+			fmt.Printf("Warning: could not write PR body: %v\n", err)
+		} else {
+			fmt.Printf("PR body written to %s\n", prBodyPath)
 		}
+	}
 
-		// Checkout the specific commit, tag, or branch.
-		fmt.Printf("Updating %s to specified ref: %s\n", path, ref)
-		if err := runCommand(submoduleDir, "git", "checkout", ref); err != nil {
-			fmt.Printf("Error checking out ref '%s' in %s: %v\n", ref, path, err)
+	if commit {
+		if err := commitSubmoduleUpdates(ctx, root, results); err != nil {
+			fmt.Printf("Error committing submodule updates: %v\n", err)
+			os.Exit(1)
 		}
-		fmt.Printf("--- Finished submodule: %s ---\n\n", path)
 	}
 
-	// Process submodules to be updated to the latest commit on their remote branch.
-	if len(submodulesToUpdateRemote) > 0 {
-		fmt.Println("--- Updating remaining submodules to latest ---")
-		// The `git submodule update --remote` command is the correct way to update
-		// submodules to the latest commit on their tracked branch. It correctly handles
-		// the "detached HEAD" state where `git pull` would fail.
-		args := []string{"submodule", "update", "--remote"}
-		args = append(args, submodulesToUpdateRemote...)
-		if err := runCommand(rootDir, "git", args...); err != nil {
-			fmt.Printf("Error updating submodules to latest: %v\n", err)
+	// Record the resolved commit for each submodule so a later `--frozen` run
+	// can reproduce this exact tree. Skip this in --frozen mode itself so a
+	// frozen run doesn't silently rewrite the lockfile it was asked to honor.
+	if !frozen {
+		lock := &Lockfile{Submodules: make(map[string]string)}
+		for _, res := range results {
+			if res.err != nil || res.newSHA == "" {
+				continue
+			}
+			lock.Submodules[res.path] = res.newSHA
+		}
+		if err := writeLockfile(rootDir, lock); err != nil {
+			fmt.Printf("Warning: could not write lockfile: %v\n", err)
+		} else {
+			fmt.Printf("Lockfile written to %s\n", lockFileName)
 		}
-		fmt.Println("--- Finished updating remaining submodules ---")
 	}
 
 	fmt.Println("Submodule update process complete.")
@@ -136,64 +197,3 @@ func parseArgs(args []string) map[string]string {
 	}
 	return targets
 }
-
-// getGitRootDir finds the root directory of the current Git repository.
-func getGitRootDir() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
-}
-
-// getSubmodules returns a slice of strings, where each string is the path to a submodule.
-func getSubmodules(rootDir string) ([]string, error) {
-	var paths []string
-	// Use `git submodule status` to list all submodules.
-	cmd := exec.Command("git", "submodule", "status")
-	cmd.Dir = rootDir
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		// The output format is ` [commit] [path] ([branch])`. We just need the path.
-		// The leading space is present on modified/uninitialized submodules.
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			paths = append(paths, fields[1])
-		}
-	}
-
-	return paths, scanner.Err()
-}
-
-// runCommand is a helper function to execute a shell command in a specified directory
-// and print its output to the console if verbose mode is enabled.
-func runCommand(dir, name string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Dir = dir // Set the working directory for the command.
-
-	if verbose {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		fmt.Printf("-> Running in %s: %s %s\n", dir, name, strings.Join(args, " "))
-		return cmd.Run()
-	}
-
-	// If not verbose, we still want to see errors from the command.
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Print the command's output only if there was an error.
-		fmt.Print(string(output))
-		return err
-	}
-	return nil
-}
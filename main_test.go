@@ -57,21 +57,3 @@ func TestParseArgs(t *testing.T) {
 		})
 	}
 }
-
-func TestRunCommandVerbose(t *testing.T) {
-	// Test verbose mode
-	originalVerbose := verbose
-	defer func() { verbose = originalVerbose }()
-
-	verbose = true
-	err := runCommand(".", "echo", "verbose test")
-	if err != nil {
-		t.Errorf("runCommand() in verbose mode failed: %v", err)
-	}
-
-	verbose = false
-	err = runCommand(".", "echo", "non-verbose test")
-	if err != nil {
-		t.Errorf("runCommand() in non-verbose mode failed: %v", err)
-	}
-}
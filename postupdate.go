@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
+)
+
+// updatedResults returns the subset of results that actually moved to a new
+// commit, sorted by path for stable output.
+func updatedResults(results []submoduleResult) []submoduleResult {
+	var updated []submoduleResult
+	for _, res := range results {
+		if res.err == nil && res.oldSHA != res.newSHA {
+			updated = append(updated, res)
+		}
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].path < updated[j].path })
+	return updated
+}
+
+// buildUpdateMessage renders the list of updated submodules, each with its
+// old..new commit range and the squashed `git log --oneline` between them,
+// in the plain-text form used for the parent repo's commit message.
+func buildUpdateMessage(results []submoduleResult) string {
+	updated := updatedResults(results)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bump %d submodule(s)\n", len(updated))
+	for _, res := range updated {
+		fmt.Fprintf(&b, "\n%s %s..%s\n", res.path, shortSHA(res.oldSHA), shortSHA(res.newSHA))
+		for _, commit := range res.commits {
+			fmt.Fprintf(&b, "  %s\n", commit)
+		}
+	}
+	return b.String()
+}
+
+// buildPRBody renders the same information as buildUpdateMessage, formatted
+// as Markdown suitable for pasting into a pull request description.
+func buildPRBody(results []submoduleResult) string {
+	updated := updatedResults(results)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Bump %d submodule(s)\n", len(updated))
+	for _, res := range updated {
+		fmt.Fprintf(&b, "\n### `%s` %s..%s\n\n", res.path, shortSHA(res.oldSHA), shortSHA(res.newSHA))
+		if len(res.commits) == 0 {
+			continue
+		}
+		for _, commit := range res.commits {
+			fmt.Fprintf(&b, "- %s\n", commit)
+		}
+	}
+	return b.String()
+}
+
+// writePRBody writes buildPRBody's Markdown output to path.
+func writePRBody(path string, results []submoduleResult) error {
+	return os.WriteFile(path, []byte(buildPRBody(results)), 0o644)
+}
+
+// commitSubmoduleUpdates stages .gitmodules and the gitlink entry for every
+// updated submodule, then creates a commit in the parent repo summarizing
+// the bump. It is a no-op if no submodule actually changed.
+func commitSubmoduleUpdates(ctx context.Context, root *gitutil.Git, results []submoduleResult) error {
+	updated := updatedResults(results)
+	if len(updated) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(updated)+1)
+	paths = append(paths, ".gitmodules")
+	for _, res := range updated {
+		paths = append(paths, res.path)
+	}
+
+	if err := root.Add(ctx, paths...); err != nil {
+		return fmt.Errorf("staging submodule updates: %w", err)
+	}
+	if err := root.Commit(ctx, buildUpdateMessage(results)); err != nil {
+		return fmt.Errorf("committing submodule updates: %w", err)
+	}
+	return nil
+}
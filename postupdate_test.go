@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
+)
+
+func sampleResults() []submoduleResult {
+	return []submoduleResult{
+		{path: "vendor/thanos", oldSHA: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", newSHA: "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", status: "updated", commits: []string{"bbbbbbb second commit", "ccccccc first commit"}},
+		{path: "vendor/loki", oldSHA: "1111111111111111111111111111111111111111", newSHA: "1111111111111111111111111111111111111111", status: "unchanged"},
+		{path: "vendor/prometheus", oldSHA: "", newSHA: "", status: "error", err: os.ErrNotExist},
+	}
+}
+
+func TestUpdatedResults(t *testing.T) {
+	updated := updatedResults(sampleResults())
+	if len(updated) != 1 {
+		t.Fatalf("updatedResults() = %v, want 1 result", updated)
+	}
+	if updated[0].path != "vendor/thanos" {
+		t.Errorf("updatedResults()[0].path = %s, want vendor/thanos", updated[0].path)
+	}
+}
+
+func TestBuildUpdateMessage(t *testing.T) {
+	msg := buildUpdateMessage(sampleResults())
+	if !strings.Contains(msg, "Bump 1 submodule(s)") {
+		t.Errorf("message missing header: %q", msg)
+	}
+	if !strings.Contains(msg, "vendor/thanos") {
+		t.Errorf("message missing updated submodule path: %q", msg)
+	}
+	if !strings.Contains(msg, "bbbbbbb second commit") {
+		t.Errorf("message missing squashed commit log: %q", msg)
+	}
+	if strings.Contains(msg, "vendor/loki") || strings.Contains(msg, "vendor/prometheus") {
+		t.Errorf("message should only list updated submodules: %q", msg)
+	}
+}
+
+func TestBuildPRBody(t *testing.T) {
+	body := buildPRBody(sampleResults())
+	if !strings.Contains(body, "## Bump 1 submodule(s)") {
+		t.Errorf("body missing Markdown header: %q", body)
+	}
+	if !strings.Contains(body, "### `vendor/thanos`") {
+		t.Errorf("body missing per-submodule heading: %q", body)
+	}
+	if !strings.Contains(body, "- bbbbbbb second commit") {
+		t.Errorf("body missing Markdown bullet for commit: %q", body)
+	}
+}
+
+func TestWritePRBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pr-body.md")
+	if err := writePRBody(path, sampleResults()); err != nil {
+		t.Fatalf("writePRBody: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	if string(got) != buildPRBody(sampleResults()) {
+		t.Errorf("writePRBody() wrote %q, want %q", got, buildPRBody(sampleResults()))
+	}
+}
+
+func TestCommitSubmoduleUpdatesNoOpWhenNothingChanged(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx := context.Background()
+	root := gitutil.New(dir, false)
+
+	results := []submoduleResult{{path: "vendor/loki", oldSHA: "x", newSHA: "x", status: "unchanged"}}
+	if err := commitSubmoduleUpdates(ctx, root, results); err != nil {
+		t.Fatalf("commitSubmoduleUpdates: %v", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git status: %v", err)
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		t.Errorf("repo should be unchanged, git status = %q", out)
+	}
+}
+
+func TestCommitSubmoduleUpdatesCreatesCommit(t *testing.T) {
+	dir, _, secondSHA := initTestRepo(t)
+	ctx := context.Background()
+	root := gitutil.New(dir, false)
+
+	if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte("[submodule \"vendor/thanos\"]\n\tpath = vendor/thanos\n\turl = https://example.com/thanos.git\n"), 0o644); err != nil {
+		t.Fatalf("writing .gitmodules: %v", err)
+	}
+
+	results := []submoduleResult{{path: "file.txt", oldSHA: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", newSHA: secondSHA, status: "updated", commits: []string{"deadbee bump vendor"}}}
+	if err := commitSubmoduleUpdates(ctx, root, results); err != nil {
+		t.Fatalf("commitSubmoduleUpdates: %v", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "log", "-1", "--pretty=%B").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	if !strings.Contains(string(out), "deadbee bump vendor") {
+		t.Errorf("commit message = %q, want it to contain the squashed log", out)
+	}
+}
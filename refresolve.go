@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
+	"github.com/saswatamcode/submoduler/internal/semver"
+)
+
+// refKind classifies how a ref string should be resolved to a concrete
+// checkout target.
+type refKind int
+
+const (
+	refLiteral     refKind = iota // a commit, tag, or branch name, used as-is
+	refSemverCaret                // "^v1.2.0": highest matching version >= v1.2.0 with the same major
+	refSemverTilde                // "~v1.2" or "~v1.2.3": highest matching version in that major.minor
+	refTagGlob                    // "tag:release-*": highest matching tag, ordered by semver
+)
+
+// classifyRef splits a ref string into its kind and the pattern/version it
+// carries (with any "^", "~", or "tag:" prefix stripped).
+func classifyRef(ref string) (refKind, string) {
+	switch {
+	case strings.HasPrefix(ref, "^"):
+		return refSemverCaret, strings.TrimPrefix(ref, "^")
+	case strings.HasPrefix(ref, "~"):
+		return refSemverTilde, strings.TrimPrefix(ref, "~")
+	case strings.HasPrefix(ref, "tag:"):
+		return refTagGlob, strings.TrimPrefix(ref, "tag:")
+	default:
+		return refLiteral, ref
+	}
+}
+
+// resolveRef turns a possibly pattern-based ref into a concrete tag name to
+// check out. Literal refs (commits, tags, branches) are returned unchanged.
+// Pattern-based refs are resolved against the submodule's remote tags,
+// picking the highest semver match; an error is returned if nothing matches.
+func resolveRef(ctx context.Context, g *gitutil.Git, ref string) (string, error) {
+	kind, pattern := classifyRef(ref)
+	if kind == refLiteral {
+		return ref, nil
+	}
+
+	tags, err := g.ListRemoteTags(ctx)
+	if err != nil {
+		return "", fmt.Errorf("listing remote tags: %w", err)
+	}
+
+	var best string
+	var bestVersion semver.Version
+	var bestParsed, found bool
+
+	for _, tag := range tags {
+		// Only refSemverCaret/refSemverTilde require the tag to be a semver
+		// version to even be a candidate. refTagGlob matches on the raw tag
+		// name first; semver is only consulted afterward to order the
+		// matches, since glob'd tags (e.g. "release-1.0.0") need not be
+		// semver-shaped themselves.
+		v, parsed := semver.Parse(tag)
+
+		var match bool
+		switch kind {
+		case refTagGlob:
+			match, err = filepath.Match(pattern, tag)
+			if err != nil {
+				return "", fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+			}
+		case refSemverCaret:
+			if !parsed {
+				continue
+			}
+			match, err = semver.MatchCaret(pattern, v)
+			if err != nil {
+				return "", err
+			}
+		case refSemverTilde:
+			if !parsed {
+				continue
+			}
+			match, err = semver.MatchTilde(pattern, v)
+			if err != nil {
+				return "", err
+			}
+		}
+		if !match {
+			continue
+		}
+
+		switch {
+		case !found:
+			best, bestVersion, bestParsed, found = tag, v, parsed, true
+		case parsed && bestParsed:
+			if semver.Compare(v, bestVersion) > 0 {
+				best, bestVersion = tag, v
+			}
+		case parsed && !bestParsed:
+			// Prefer a semver-parseable match over a lexically-ordered one.
+			best, bestVersion, bestParsed = tag, v, true
+		case !parsed && !bestParsed:
+			if tag > best {
+				best = tag
+			}
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("no tag matching %q found", ref)
+	}
+	return best, nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
+)
+
+func TestClassifyRef(t *testing.T) {
+	tests := []struct {
+		ref         string
+		wantKind    refKind
+		wantPattern string
+	}{
+		{"v1.2.3", refLiteral, "v1.2.3"},
+		{"a1b2c3d", refLiteral, "a1b2c3d"},
+		{"^v1.2.0", refSemverCaret, "v1.2.0"},
+		{"~v1.2", refSemverTilde, "v1.2"},
+		{"tag:release-*", refTagGlob, "release-*"},
+	}
+
+	for _, tt := range tests {
+		kind, pattern := classifyRef(tt.ref)
+		if kind != tt.wantKind || pattern != tt.wantPattern {
+			t.Errorf("classifyRef(%q) = (%v, %q), want (%v, %q)", tt.ref, kind, pattern, tt.wantKind, tt.wantPattern)
+		}
+	}
+}
+
+// tagTestRepo creates a bare-enough local repo with a handful of tags so
+// resolveRef can be exercised against `git ls-remote --tags` on a local path.
+func tagTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+	run("commit", "-q", "--allow-empty", "-m", "root")
+	for _, tag := range []string{"v1.0.0", "v1.2.0", "v1.2.5", "v1.9.9", "v2.0.0", "not-a-version", "release-1.0.0", "release-1.2.0", "release-2.0.0"} {
+		run("tag", tag)
+	}
+	// ListRemoteTags runs `git ls-remote --tags` against the "origin" remote,
+	// as it would for a real submodule; point it at the repo itself.
+	run("remote", "add", "origin", dir)
+
+	return dir
+}
+
+func TestResolveRefLiteralPassesThrough(t *testing.T) {
+	g := gitutil.New(tagTestRepo(t), false)
+	got, err := resolveRef(context.Background(), g, "v1.2.0")
+	if err != nil {
+		t.Fatalf("resolveRef: %v", err)
+	}
+	if got != "v1.2.0" {
+		t.Errorf("resolveRef(literal) = %q, want unchanged %q", got, "v1.2.0")
+	}
+}
+
+func TestResolveRefSemverCaret(t *testing.T) {
+	g := gitutil.New(tagTestRepo(t), false)
+	got, err := resolveRef(context.Background(), g, "^v1.2.0")
+	if err != nil {
+		t.Fatalf("resolveRef: %v", err)
+	}
+	if got != "v1.9.9" {
+		t.Errorf("resolveRef(^v1.2.0) = %q, want v1.9.9 (highest 1.x >= 1.2.0)", got)
+	}
+}
+
+func TestResolveRefSemverTilde(t *testing.T) {
+	g := gitutil.New(tagTestRepo(t), false)
+	got, err := resolveRef(context.Background(), g, "~v1.2")
+	if err != nil {
+		t.Fatalf("resolveRef: %v", err)
+	}
+	if got != "v1.2.5" {
+		t.Errorf("resolveRef(~v1.2) = %q, want v1.2.5 (highest 1.2.x)", got)
+	}
+}
+
+func TestResolveRefTagGlob(t *testing.T) {
+	g := gitutil.New(tagTestRepo(t), false)
+	got, err := resolveRef(context.Background(), g, "tag:v1.*")
+	if err != nil {
+		t.Fatalf("resolveRef: %v", err)
+	}
+	if got != "v1.9.9" {
+		t.Errorf("resolveRef(tag:v1.*) = %q, want v1.9.9 (highest matching v1.*)", got)
+	}
+}
+
+func TestResolveRefTagGlobNonSemverTags(t *testing.T) {
+	g := gitutil.New(tagTestRepo(t), false)
+	got, err := resolveRef(context.Background(), g, "tag:release-*")
+	if err != nil {
+		t.Fatalf("resolveRef: %v", err)
+	}
+	if got != "release-2.0.0" {
+		t.Errorf("resolveRef(tag:release-*) = %q, want release-2.0.0 (highest matching release-*, ordered lexically since the tags aren't semver-shaped)", got)
+	}
+}
+
+func TestResolveRefNoMatchErrors(t *testing.T) {
+	g := gitutil.New(tagTestRepo(t), false)
+	if _, err := resolveRef(context.Background(), g, "^v5.0.0"); err == nil {
+		t.Error("resolveRef should error when no tag matches the range")
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
+)
+
+// submoduleJob describes one unit of work for a worker: check out a specific
+// ref, or, when ref is empty, update to the latest commit on the submodule's
+// tracked remote branch.
+type submoduleJob struct {
+	path string
+	ref  string
+}
+
+// submoduleResult is the outcome of processing one submoduleJob. Results are
+// collected into the summary table (or dry-run plan) printed at the end of
+// a run. commits lists the log between oldSHA and newSHA whenever the two
+// differ, for use by the dry-run plan and by --commit's generated message.
+type submoduleResult struct {
+	path    string
+	oldSHA  string
+	newSHA  string
+	status  string
+	commits []string
+	err     error
+}
+
+// runSubmoduleJobs runs jobs across a bounded pool of workers, cancelling
+// in-flight and not-yet-started work if ctx is done (e.g. on Ctrl-C). In
+// dry-run mode, no submodule is actually checked out or updated; only the
+// target commit is resolved. It returns one result per job, in no
+// particular order.
+func runSubmoduleJobs(ctx context.Context, rootDir string, jobs []submoduleJob, workers int, dryRun bool) []submoduleResult {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobCh := make(chan submoduleJob)
+	resultCh := make(chan submoduleResult, len(jobs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobCh {
+				resultCh <- processSubmoduleJob(ctx, rootDir, job, dryRun)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobCh)
+		for _, job := range jobs {
+			select {
+			case jobCh <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	results := make([]submoduleResult, 0, len(jobs))
+	for res := range resultCh {
+		results = append(results, res)
+	}
+	return results
+}
+
+// processSubmoduleJob resolves one submodule to its target ref (or the
+// latest commit on its tracked branch), reporting the old and new SHA. In
+// dry-run mode the target commit is resolved but never checked out, and the
+// commits between old and new are summarized for the plan.
+func processSubmoduleJob(ctx context.Context, rootDir string, job submoduleJob, dryRun bool) submoduleResult {
+	sub := gitutil.New(rootDir+"/"+job.path, verbose)
+	res := submoduleResult{path: job.path}
+
+	if err := ctx.Err(); err != nil {
+		res.status = "cancelled"
+		res.err = err
+		return res
+	}
+
+	oldSHA, err := sub.RevParse(ctx, "HEAD")
+	if err != nil {
+		res.status = "error"
+		res.err = fmt.Errorf("resolving current commit: %w", err)
+		return res
+	}
+	res.oldSHA = oldSHA
+
+	newSHA, err := resolveTargetSHA(ctx, rootDir, sub, job, dryRun)
+	if err != nil {
+		res.status = "error"
+		res.err = err
+		return res
+	}
+	res.newSHA = newSHA
+
+	unchangedStatus, changedStatus := "unchanged", "updated"
+	if dryRun {
+		unchangedStatus, changedStatus = "up to date", "would update"
+	}
+	if oldSHA == newSHA {
+		res.status = unchangedStatus
+		return res
+	}
+	res.status = changedStatus
+
+	// The commit range is useful beyond dry-run mode too: --commit reuses it
+	// to build the parent-repo commit message and PR body.
+	if commits, err := sub.LogOneline(ctx, oldSHA, newSHA); err == nil {
+		res.commits = commits
+	}
+	return res
+}
+
+// resolveTargetSHA determines the commit a submodule should move to. In
+// dry-run mode it only resolves the SHA. All git operations, including the
+// "latest" path below, run inside the submodule's own directory (sub) so
+// that concurrent jobs never touch the parent repo's shared index.
+func resolveTargetSHA(ctx context.Context, rootDir string, sub *gitutil.Git, job submoduleJob, dryRun bool) (string, error) {
+	if job.ref != "" {
+		if err := sub.Fetch(ctx, gitutil.FetchOpts{All: true, Tags: true}); err != nil {
+			return "", fmt.Errorf("fetching: %w", err)
+		}
+		ref, err := resolveRef(ctx, sub, job.ref)
+		if err != nil {
+			return "", fmt.Errorf("resolving ref %q: %w", job.ref, err)
+		}
+
+		if dryRun {
+			sha, err := sub.RevParse(ctx, ref)
+			if err != nil {
+				return "", fmt.Errorf("resolving target ref %q: %w", ref, err)
+			}
+			return sha, nil
+		}
+
+		if err := sub.Checkout(ctx, ref); err != nil {
+			return "", fmt.Errorf("checking out ref %q: %w", ref, err)
+		}
+		return sub.RevParse(ctx, "HEAD")
+	}
+
+	branch, err := gitutil.SubmoduleBranch(ctx, rootDir, job.path)
+	if err != nil {
+		return "", fmt.Errorf("reading tracked branch: %w", err)
+	}
+
+	if dryRun {
+		sha, err := sub.LsRemoteRef(ctx, branch)
+		if err != nil {
+			return "", fmt.Errorf("resolving latest remote commit: %w", err)
+		}
+		return sha, nil
+	}
+
+	if err := sub.Fetch(ctx, gitutil.FetchOpts{All: true, Tags: true}); err != nil {
+		return "", fmt.Errorf("fetching: %w", err)
+	}
+	if branch == "" {
+		branch, err = sub.RemoteDefaultBranch(ctx)
+		if err != nil {
+			return "", fmt.Errorf("determining default branch: %w", err)
+		}
+	}
+	if err := sub.Checkout(ctx, "origin/"+branch); err != nil {
+		return "", fmt.Errorf("checking out origin/%s: %w", branch, err)
+	}
+	return sub.RevParse(ctx, "HEAD")
+}
+
+// printSummary prints a table of path, old SHA, new SHA, and status for each
+// processed submodule, sorted by path for stable output.
+func printSummary(results []submoduleResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	sorted := make([]submoduleResult, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].path < sorted[j].path })
+
+	fmt.Println("\n--- Summary ---")
+	fmt.Printf("%-40s %-10s %-10s %s\n", "PATH", "OLD", "NEW", "STATUS")
+	for _, res := range sorted {
+		status := res.status
+		if res.err != nil {
+			status = fmt.Sprintf("%s (%v)", status, res.err)
+		}
+		fmt.Printf("%-40s %-10s %-10s %s\n", res.path, shortSHA(res.oldSHA), shortSHA(res.newSHA), status)
+	}
+	fmt.Println("---------------")
+}
+
+// printPlan prints what a real run would change, one line per submodule:
+// "path  old_sha..new_sha  (N commits, first-line log)". Submodules already
+// at their target are reported as up to date instead.
+func printPlan(results []submoduleResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	sorted := make([]submoduleResult, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].path < sorted[j].path })
+
+	fmt.Println("\n--- Dry run: plan ---")
+	for _, res := range sorted {
+		if res.err != nil {
+			fmt.Printf("%-40s error: %v\n", res.path, res.err)
+			continue
+		}
+		if res.oldSHA == res.newSHA {
+			fmt.Printf("%-40s up to date (%s)\n", res.path, shortSHA(res.oldSHA))
+			continue
+		}
+
+		detail := ""
+		if len(res.commits) > 0 {
+			detail = fmt.Sprintf("  (%d commits, %s)", len(res.commits), res.commits[0])
+		}
+		fmt.Printf("%-40s %s..%s%s\n", res.path, shortSHA(res.oldSHA), shortSHA(res.newSHA), detail)
+	}
+	fmt.Println("---------------------")
+}
+
+// shortSHA returns the short form of a commit SHA for display, or "-" if empty.
+func shortSHA(sha string) string {
+	if sha == "" {
+		return "-"
+	}
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
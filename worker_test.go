@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saswatamcode/submoduler/internal/gitutil"
+)
+
+// initTestRepo creates a small git repository with two commits on main and
+// returns its directory along with the SHA of each commit.
+func initTestRepo(t *testing.T) (dir string, firstSHA string, secondSHA string) {
+	t.Helper()
+	dir = t.TempDir()
+	ctx := context.Background()
+	g := gitutil.New(dir, false)
+
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one"), 0o644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "first")
+	firstSHA, err := g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("two"), 0o644); err != nil {
+		t.Fatalf("writing file: %v", err)
+	}
+	run("commit", "-q", "-am", "second")
+	secondSHA, err = g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+
+	return dir, firstSHA, secondSHA
+}
+
+func TestProcessSubmoduleJobCheckout(t *testing.T) {
+	dir, firstSHA, secondSHA := initTestRepo(t)
+	ctx := context.Background()
+
+	res := processSubmoduleJob(ctx, filepath.Dir(dir), submoduleJob{path: filepath.Base(dir), ref: firstSHA}, false)
+	if res.err != nil {
+		t.Fatalf("processSubmoduleJob() returned error: %v", res.err)
+	}
+	if res.oldSHA != secondSHA {
+		t.Errorf("oldSHA = %s, want %s", res.oldSHA, secondSHA)
+	}
+	if res.newSHA != firstSHA {
+		t.Errorf("newSHA = %s, want %s", res.newSHA, firstSHA)
+	}
+	if res.status != "updated" {
+		t.Errorf("status = %s, want updated", res.status)
+	}
+}
+
+func TestProcessSubmoduleJobDryRun(t *testing.T) {
+	dir, firstSHA, secondSHA := initTestRepo(t)
+	ctx := context.Background()
+	g := gitutil.New(dir, false)
+
+	// Start from the older commit so the dry-run target (secondSHA) is ahead
+	// of HEAD and there are commits in between to report.
+	if err := g.Checkout(ctx, firstSHA); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	res := processSubmoduleJob(ctx, filepath.Dir(dir), submoduleJob{path: filepath.Base(dir), ref: secondSHA}, true)
+	if res.err != nil {
+		t.Fatalf("processSubmoduleJob() returned error: %v", res.err)
+	}
+	if res.oldSHA != firstSHA {
+		t.Errorf("oldSHA = %s, want %s", res.oldSHA, firstSHA)
+	}
+	if res.newSHA != secondSHA {
+		t.Errorf("newSHA = %s, want %s", res.newSHA, secondSHA)
+	}
+	if res.status != "would update" {
+		t.Errorf("status = %s, want %q", res.status, "would update")
+	}
+	if len(res.commits) == 0 {
+		t.Error("expected commits to be populated in dry-run mode")
+	}
+
+	// Dry-run must not actually move HEAD.
+	head, err := g.RevParse(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("RevParse: %v", err)
+	}
+	if head != firstSHA {
+		t.Errorf("HEAD = %s after dry run, want unchanged %s", head, firstSHA)
+	}
+}
+
+// upstreamRepo creates a small "remote" repository with two commits and
+// returns its directory along with the SHA of each commit.
+func upstreamRepo(t *testing.T) (dir, firstSHA, secondSHA string) {
+	t.Helper()
+	return initTestRepo(t)
+}
+
+// cloneSubmoduleAtFirstCommit clones upstream into rootDir/path, checked out
+// at firstSHA, the way a submodule would look before being updated.
+func cloneSubmoduleAtFirstCommit(t *testing.T, rootDir, path, upstream, firstSHA string) {
+	t.Helper()
+	ctx := context.Background()
+	subDir := filepath.Join(rootDir, path)
+
+	if out, err := exec.CommandContext(ctx, "git", "clone", "-q", upstream, subDir).CombinedOutput(); err != nil {
+		t.Fatalf("git clone: %v\n%s", err, out)
+	}
+	if err := gitutil.New(subDir, false).Checkout(ctx, firstSHA); err != nil {
+		t.Fatalf("checking out %s in %s: %v", firstSHA, subDir, err)
+	}
+}
+
+// TestResolveTargetSHALatestRunsEntirelyWithinSubmodule runs several
+// "latest" (no explicit ref) jobs concurrently and asserts they only ever
+// touch each submodule's own directory. rootDir is deliberately NOT a git
+// repository here: if resolving the latest commit ever shelled out to git
+// against rootDir (as `git submodule update --remote` does), every job
+// would fail immediately with "not a git repository".
+func TestResolveTargetSHALatestRunsEntirelyWithinSubmodule(t *testing.T) {
+	upstream, firstSHA, secondSHA := upstreamRepo(t)
+	rootDir := t.TempDir()
+
+	const numSubmodules = 4
+	var jobs []submoduleJob
+	var gitmodules strings.Builder
+	for i := 0; i < numSubmodules; i++ {
+		path := fmt.Sprintf("sub%d", i)
+		cloneSubmoduleAtFirstCommit(t, rootDir, path, upstream, firstSHA)
+		jobs = append(jobs, submoduleJob{path: path})
+		fmt.Fprintf(&gitmodules, "[submodule %q]\n\tpath = %s\n\turl = %s\n", path, path, upstream)
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, ".gitmodules"), []byte(gitmodules.String()), 0o644); err != nil {
+		t.Fatalf("writing .gitmodules: %v", err)
+	}
+
+	results := runSubmoduleJobs(context.Background(), rootDir, jobs, numSubmodules, false)
+	if len(results) != numSubmodules {
+		t.Fatalf("len(results) = %d, want %d", len(results), numSubmodules)
+	}
+	for _, res := range results {
+		if res.err != nil {
+			t.Errorf("%s: processSubmoduleJob() returned error: %v", res.path, res.err)
+			continue
+		}
+		if res.oldSHA != firstSHA {
+			t.Errorf("%s: oldSHA = %s, want %s", res.path, res.oldSHA, firstSHA)
+		}
+		if res.newSHA != secondSHA {
+			t.Errorf("%s: newSHA = %s, want %s", res.path, res.newSHA, secondSHA)
+		}
+		if res.status != "updated" {
+			t.Errorf("%s: status = %s, want updated", res.path, res.status)
+		}
+	}
+}
+
+func TestProcessSubmoduleJobCancelled(t *testing.T) {
+	dir, _, _ := initTestRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	res := processSubmoduleJob(ctx, filepath.Dir(dir), submoduleJob{path: filepath.Base(dir), ref: "HEAD"}, false)
+	if res.status != "cancelled" {
+		t.Errorf("status = %s, want cancelled", res.status)
+	}
+	if res.err == nil {
+		t.Error("expected an error for a cancelled job, got nil")
+	}
+}
+
+func TestRunSubmoduleJobsBoundedWorkers(t *testing.T) {
+	dir, firstSHA, _ := initTestRepo(t)
+	ctx := context.Background()
+
+	jobs := []submoduleJob{{path: filepath.Base(dir), ref: firstSHA}}
+	results := runSubmoduleJobs(ctx, filepath.Dir(dir), jobs, 2, false)
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %d, want 1", len(results))
+	}
+	if results[0].err != nil {
+		t.Errorf("unexpected error: %v", results[0].err)
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", "-"},
+		{"abc", "abc"},
+		{"0123456789abcdef", "0123456789"},
+	}
+	for _, tt := range tests {
+		if got := shortSHA(tt.in); got != tt.want {
+			t.Errorf("shortSHA(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}